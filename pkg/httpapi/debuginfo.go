@@ -0,0 +1,29 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/profefe/profefe/pkg/profile"
+)
+
+// HandleCreateDebugInfo handles POST /api/0/debuginfo?build_id=...: it
+// uploads a debug-info blob (an unstripped binary or standalone DWARF file)
+// ahead of time, for repo's Symbolizer to use when ingesting profiles that
+// reference the same BuildID.
+func (h *Handler) HandleCreateDebugInfo(w http.ResponseWriter, r *http.Request) {
+	buildID := r.URL.Query().Get("build_id")
+
+	info := &profile.DebugInfo{BuildID: buildID}
+	if err := info.Validate(); err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.CreateDebugInfo(r.Context(), buildID, r.Body); err != nil {
+		h.logger.Error("could not store debug info", "err", err, "build_id", buildID)
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,44 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/profefe/profefe/pkg/profile"
+)
+
+// deltaRequest is the body of POST /api/0/profiles/delta: two time ranges /
+// label sets to diff, plus an optional strategy for which sample types get
+// diffed versus summed.
+type deltaRequest struct {
+	Base     profile.GetProfilesRequest `json:"base"`
+	Target   profile.GetProfilesRequest `json:"target"`
+	Strategy *profile.DeltaStrategy     `json:"strategy,omitempty"`
+}
+
+// HandleGetProfileDelta handles POST /api/0/profiles/delta: it decodes a
+// base and target window from the request body and streams back
+// target-base as computed by Repository.GetProfileDelta.
+func (h *Handler) HandleGetProfileDelta(w http.ResponseWriter, r *http.Request) {
+	var req deltaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := req.Base.Validate(); err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := req.Target.Validate(); err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	err := h.repo.GetProfileDeltaTo(r.Context(), &req.Base, &req.Target, req.Strategy, w)
+	if err != nil {
+		h.logger.Error("could not compute profile delta", "err", err, "req", req)
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,26 @@
+// Package httpapi exposes profile.Repository over HTTP.
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/profefe/profefe/pkg/logger"
+	"github.com/profefe/profefe/pkg/profile"
+)
+
+// Handler wires HTTP endpoints onto a profile.Repository.
+type Handler struct {
+	logger *logger.Logger
+	repo   *profile.Repository
+}
+
+func NewHandler(log *logger.Logger, repo *profile.Repository) *Handler {
+	return &Handler{
+		logger: log,
+		repo:   repo,
+	}
+}
+
+func writeError(w http.ResponseWriter, err error, status int) {
+	http.Error(w, err.Error(), status)
+}
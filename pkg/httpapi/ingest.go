@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/profefe/profefe/pkg/profile"
+)
+
+// HandleCreateProfiles handles POST /api/0/profiles/batch: an authenticated
+// agent uploads a multipart batch of profiles collected over an interval.
+// Each part carries one pprof body; its metadata travels in the part's MIME
+// header as Profefe-Type, Profefe-Created-At (RFC3339), Profefe-Labels
+// (comma-separated key=value pairs) and, optionally, Profefe-Duration (a
+// time.Duration string). The response is the ScheduleHint telling the agent
+// what to collect next.
+func (h *Handler) HandleCreateProfiles(w http.ResponseWriter, r *http.Request) {
+	req := &profile.BatchIngestRequest{
+		ID:    r.URL.Query().Get("id"),
+		Token: r.Header.Get("Authorization"),
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	var items []*profile.IngestItem
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		var ptype profile.ProfileType
+		if err := ptype.FromString(part.Header.Get("Profefe-Type")); err != nil {
+			writeError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		createdAt, err := time.Parse(time.RFC3339, part.Header.Get("Profefe-Created-At"))
+		if err != nil {
+			writeError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		var duration time.Duration
+		if v := part.Header.Get("Profefe-Duration"); v != "" {
+			duration, err = time.ParseDuration(v)
+			if err != nil {
+				writeError(w, err, http.StatusBadRequest)
+				return
+			}
+		}
+
+		// The next call to NextPart discards whatever is left of this part,
+		// so its body is read now, before the batch is handed to the repo.
+		body, err := io.ReadAll(part)
+		if err != nil {
+			writeError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		items = append(items, &profile.IngestItem{
+			Type:      ptype,
+			Labels:    parseLabelsHeader(part.Header.Get("Profefe-Labels")),
+			Duration:  duration,
+			CreatedAt: createdAt,
+			Body:      bytes.NewReader(body),
+		})
+	}
+
+	hint, err := h.repo.CreateProfiles(r.Context(), req, items)
+	if err != nil {
+		h.logger.Error("could not ingest profile batch", "err", err, "id", req.ID)
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hint)
+}
+
+// parseLabelsHeader parses a Profefe-Labels header value of the form
+// "key1=value1,key2=value2" into profile.Labels. Entries without an "="
+// are skipped rather than rejected, so a malformed label doesn't fail an
+// otherwise-valid upload.
+func parseLabelsHeader(s string) profile.Labels {
+	if s == "" {
+		return nil
+	}
+
+	var labels profile.Labels
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		labels = append(labels, profile.Label{
+			Key:   strings.TrimSpace(k),
+			Value: strings.TrimSpace(v),
+		})
+	}
+	return labels
+}
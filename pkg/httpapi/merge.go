@@ -0,0 +1,31 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/profefe/profefe/pkg/profile"
+)
+
+// HandleMergeProfile handles POST /api/0/profiles/merge: it decodes a
+// profile.GetProfilesRequest from the request body, merges every matching
+// profile via Repository.MergeProfiles, and streams the result back as a
+// single pprof profile.
+func (h *Handler) HandleMergeProfile(w http.ResponseWriter, r *http.Request) {
+	var req profile.GetProfilesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if err := h.repo.MergeProfilesTo(r.Context(), &req, w); err != nil {
+		h.logger.Error("could not merge profiles", "err", err, "req", req)
+		writeError(w, err, http.StatusInternalServerError)
+		return
+	}
+}
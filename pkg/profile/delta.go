@@ -0,0 +1,111 @@
+package profile
+
+import (
+	"context"
+	"io"
+
+	"github.com/profefe/profefe/internal/pprof/profile"
+	"golang.org/x/xerrors"
+)
+
+// DeltaStrategy controls how GetProfileDelta reconciles sample types that
+// behave differently across a diff. Heap profiles are the motivating case:
+// you want inuse_space diffed (target-base), but alloc_space summed across
+// the window, since the latter only ever grows.
+type DeltaStrategy struct {
+	// DiffSampleTypes lists the pprof sample type names (e.g. "inuse_space")
+	// to compute as target-base. Sample types not listed here are summed
+	// across base and target instead of diffed.
+	DiffSampleTypes []string
+}
+
+// defaultDeltaStrategy diffs the CPU sample types (cpu, samples) and the
+// in-use heap gauges (inuse_space, inuse_objects), and sums everything else,
+// alloc_space and alloc_objects included.
+var defaultDeltaStrategy = DeltaStrategy{
+	DiffSampleTypes: []string{"cpu", "samples", "inuse_space", "inuse_objects"},
+}
+
+// GetProfileDelta merges the profiles matching base and target, each into
+// its own profile.Profile, and returns target-base: what got hotter (or
+// colder) between the two windows. strategy controls which sample types are
+// diffed versus summed; pass nil to use defaultDeltaStrategy.
+//
+// base and target must resolve to profiles with identical sample types;
+// otherwise GetProfileDelta returns an error rather than a bogus diff.
+func (repo *Repository) GetProfileDelta(ctx context.Context, base, target *GetProfilesRequest, strategy *DeltaStrategy) (*profile.Profile, error) {
+	if strategy == nil {
+		strategy = &defaultDeltaStrategy
+	}
+
+	basePP, err := repo.MergeProfiles(ctx, base)
+	if err != nil {
+		return nil, xerrors.Errorf("could not merge base profiles: %w", err)
+	}
+
+	targetPP, err := repo.MergeProfiles(ctx, target)
+	if err != nil {
+		return nil, xerrors.Errorf("could not merge target profiles: %w", err)
+	}
+
+	if err := validateSameSampleTypes(basePP, targetPP); err != nil {
+		return nil, xerrors.Errorf("base and target profiles are not comparable: %w", err)
+	}
+
+	negateSampleTypes(basePP, strategy.DiffSampleTypes)
+
+	delta, err := profile.Merge([]*profile.Profile{basePP, targetPP})
+	if err != nil {
+		return nil, xerrors.Errorf("could not compute profile delta: %w", err)
+	}
+
+	return delta, nil
+}
+
+// GetProfileDeltaTo is like GetProfileDelta, but writes the resulting
+// profile to w instead of returning it.
+func (repo *Repository) GetProfileDeltaTo(ctx context.Context, base, target *GetProfilesRequest, strategy *DeltaStrategy, w io.Writer) error {
+	pp, err := repo.GetProfileDelta(ctx, base, target, strategy)
+	if err != nil {
+		return err
+	}
+	return pp.Write(w)
+}
+
+func validateSameSampleTypes(base, target *profile.Profile) error {
+	if len(base.SampleType) != len(target.SampleType) {
+		return xerrors.Errorf("sample type count mismatch: base has %d, target has %d", len(base.SampleType), len(target.SampleType))
+	}
+	for i, st := range base.SampleType {
+		if target.SampleType[i].Type != st.Type || target.SampleType[i].Unit != st.Unit {
+			return xerrors.Errorf(
+				"sample type mismatch at index %d: base is %s/%s, target is %s/%s",
+				i, st.Type, st.Unit, target.SampleType[i].Type, target.SampleType[i].Unit,
+			)
+		}
+	}
+	return nil
+}
+
+// negateSampleTypes flips the sign of every sample value for the listed
+// sample types, so that a subsequent profile.Merge with the untouched
+// target profile computes target-base for those columns, while still
+// summing the rest.
+func negateSampleTypes(pp *profile.Profile, diffTypes []string) {
+	negate := make(map[int]bool, len(diffTypes))
+	for i, st := range pp.SampleType {
+		for _, name := range diffTypes {
+			if st.Type == name {
+				negate[i] = true
+			}
+		}
+	}
+
+	for _, sample := range pp.Sample {
+		for i := range negate {
+			if i < len(sample.Value) {
+				sample.Value[i] = -sample.Value[i]
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package profile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/profefe/profefe/internal/pprof/profile"
+)
+
+func TestNegateSampleTypes(t *testing.T) {
+	pp := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "inuse_space", Unit: "bytes"},
+			{Type: "alloc_space", Unit: "bytes"},
+		},
+		Sample: []*profile.Sample{
+			{Value: []int64{100, 10}},
+		},
+	}
+
+	negateSampleTypes(pp, []string{"inuse_space"})
+
+	got := pp.Sample[0].Value
+	if got[0] != -100 {
+		t.Errorf("inuse_space = %d, want -100 (negated)", got[0])
+	}
+	if got[1] != 10 {
+		t.Errorf("alloc_space = %d, want 10 (untouched)", got[1])
+	}
+}
+
+func TestValidateSameSampleTypesMismatch(t *testing.T) {
+	base := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "inuse_space", Unit: "bytes"}},
+	}
+	target := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "alloc_space", Unit: "bytes"}},
+	}
+
+	if err := validateSameSampleTypes(base, target); err == nil {
+		t.Fatal("expected an error for mismatched sample types, got nil")
+	}
+}
+
+func TestValidateSameSampleTypesMatch(t *testing.T) {
+	base := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+	}
+	target := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+	}
+
+	if err := validateSameSampleTypes(base, target); err != nil {
+		t.Errorf("validateSameSampleTypes: %v", err)
+	}
+}
+
+type fakeDeltaStorage struct {
+	profilesByType map[ProfileType][]*profile.Profile
+}
+
+func (s *fakeDeltaStorage) CreateService(ctx context.Context, svc *Service) error { return nil }
+
+func (s *fakeDeltaStorage) GetServices(ctx context.Context, filter *GetServicesFilter) ([]*Service, error) {
+	return nil, nil
+}
+
+func (s *fakeDeltaStorage) CreateProfile(ctx context.Context, prof *Profile, pp *profile.Profile) error {
+	return nil
+}
+
+func (s *fakeDeltaStorage) CreateProfiles(ctx context.Context, profs []*Profile, pps []*profile.Profile) error {
+	return nil
+}
+
+func (s *fakeDeltaStorage) GetProfiles(ctx context.Context, filter *GetProfileFilter) ([]*profile.Profile, error) {
+	return s.profilesByType[filter.Type], nil
+}
+
+func (s *fakeDeltaStorage) GetProfile(ctx context.Context, filter *GetProfileFilter) (*profile.Profile, error) {
+	pps, err := s.GetProfiles(ctx, filter)
+	if err != nil || len(pps) == 0 {
+		return nil, err
+	}
+	return pps[0], nil
+}
+
+func newDeltaRequest(ptype ProfileType) *GetProfilesRequest {
+	return &GetProfilesRequest{
+		Service: "svc",
+		Type:    ptype,
+		From:    time.Now().Add(-time.Hour),
+		To:      time.Now(),
+	}
+}
+
+func TestGetProfileDeltaSignFlip(t *testing.T) {
+	mkProfile := func(v int64) *profile.Profile {
+		return &profile.Profile{
+			SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+			Sample:     []*profile.Sample{{Value: []int64{v}}},
+		}
+	}
+
+	storage := &fakeDeltaStorage{
+		profilesByType: map[ProfileType][]*profile.Profile{
+			CPUProfile:  {mkProfile(5)},
+			HeapProfile: {mkProfile(12)},
+		},
+	}
+	repo := NewRepository(nil, storage)
+
+	delta, err := repo.GetProfileDelta(
+		context.Background(),
+		newDeltaRequest(CPUProfile),
+		newDeltaRequest(HeapProfile),
+		&DeltaStrategy{DiffSampleTypes: []string{"samples"}},
+	)
+	if err != nil {
+		t.Fatalf("GetProfileDelta: %v", err)
+	}
+
+	if got := delta.Sample[0].Value[0]; got != 7 {
+		t.Errorf("delta = %d, want 7 (12-5)", got)
+	}
+}
+
+func TestGetProfileDeltaSampleTypeMismatch(t *testing.T) {
+	storage := &fakeDeltaStorage{
+		profilesByType: map[ProfileType][]*profile.Profile{
+			CPUProfile: {{
+				SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+				Sample:     []*profile.Sample{{Value: []int64{1}}},
+			}},
+			HeapProfile: {{
+				SampleType: []*profile.ValueType{{Type: "inuse_space", Unit: "bytes"}},
+				Sample:     []*profile.Sample{{Value: []int64{1}}},
+			}},
+		},
+	}
+	repo := NewRepository(nil, storage)
+
+	_, err := repo.GetProfileDelta(context.Background(), newDeltaRequest(CPUProfile), newDeltaRequest(HeapProfile), nil)
+	if err == nil {
+		t.Fatal("expected an error for mismatched sample types between base and target, got nil")
+	}
+}
@@ -0,0 +1,136 @@
+package profile
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/profefe/profefe/internal/pprof/profile"
+	"golang.org/x/xerrors"
+)
+
+// BatchIngestRequest is the authenticated batch-upload request for a
+// continuous profiling agent: one ID/token pair covering every profile
+// collected over an interval. Per-profile metadata (type/labels/duration/
+// timestamp) travels alongside each item instead of on the request itself.
+type BatchIngestRequest struct {
+	ID    string
+	Token string
+}
+
+func (req *BatchIngestRequest) Validate() error {
+	if req == nil {
+		return xerrors.New("nil request")
+	}
+	if req.ID == "" {
+		return xerrors.Errorf("id empty: req %v", req)
+	}
+	if req.Token == "" {
+		return xerrors.Errorf("token empty: req %v", req)
+	}
+	return nil
+}
+
+// IngestItem is one profile out of a batch upload: its own metadata plus
+// the raw pprof body it was collected into.
+type IngestItem struct {
+	Type      ProfileType
+	Labels    Labels
+	Duration  time.Duration
+	CreatedAt time.Time
+
+	Body io.Reader
+}
+
+func (item *IngestItem) Validate() error {
+	if item == nil {
+		return xerrors.New("nil item")
+	}
+	if item.Type == UnknownProfile {
+		return xerrors.Errorf("unknown profile type %s: item %v", item.Type, item)
+	}
+	if item.CreatedAt.IsZero() {
+		return xerrors.Errorf("createdAt time zero: item %v", item)
+	}
+	return nil
+}
+
+// ScheduleHint tells an agent what to collect next, and for how long, e.g.
+// "collect mutex for 30s". A hint names one profile type and one duration;
+// it holds no sequence of steps. It's the server's way of steering or
+// rate-limiting a whole fleet of agents.
+type ScheduleHint struct {
+	Type     ProfileType
+	Duration time.Duration
+}
+
+// ProfileScheduler computes the next ScheduleHint for a service after it
+// finishes a batch ingest. Policies can use fixed rotations, load-based
+// heuristics, or anything else derived from the service's recent ingest
+// activity.
+type ProfileScheduler interface {
+	Next(ctx context.Context, service string, ingested []*IngestItem) (ScheduleHint, error)
+}
+
+// fixedScheduler is a ProfileScheduler that always returns the same hint,
+// regardless of recent activity.
+type fixedScheduler struct {
+	hint ScheduleHint
+}
+
+// NewFixedScheduler returns a ProfileScheduler that always hints hint. It's
+// a reasonable default for deployments that don't need load-based steering.
+func NewFixedScheduler(hint ScheduleHint) ProfileScheduler {
+	return fixedScheduler{hint: hint}
+}
+
+func (s fixedScheduler) Next(ctx context.Context, service string, ingested []*IngestItem) (ScheduleHint, error) {
+	return s.hint, nil
+}
+
+// CreateProfiles ingests a batch of profiles collected by an agent under a
+// single ID/token, persists them in one storage call, and returns the
+// ScheduleHint for what the agent should collect next, as computed by
+// repo's ProfileScheduler. If no scheduler is configured, it returns the
+// zero ScheduleHint.
+func (repo *Repository) CreateProfiles(ctx context.Context, req *BatchIngestRequest, items []*IngestItem) (ScheduleHint, error) {
+	if err := req.Validate(); err != nil {
+		return ScheduleHint{}, err
+	}
+
+	service := &Service{
+		BuildID: req.ID,
+		Token:   TokenFromString(req.Token),
+	}
+
+	profs := make([]*Profile, 0, len(items))
+	pps := make([]*profile.Profile, 0, len(items))
+	for _, item := range items {
+		if err := item.Validate(); err != nil {
+			return ScheduleHint{}, err
+		}
+
+		pp, err := profile.Parse(item.Body)
+		if err != nil {
+			return ScheduleHint{}, xerrors.Errorf("could not parse profile: %w", err)
+		}
+
+		symbolizeProfile(ctx, repo.symbolizer, pp)
+
+		profs = append(profs, &Profile{
+			Type:    item.Type,
+			Labels:  item.Labels,
+			Service: service,
+		})
+		pps = append(pps, pp)
+	}
+
+	if err := repo.storage.CreateProfiles(ctx, profs, pps); err != nil {
+		return ScheduleHint{}, err
+	}
+
+	if repo.scheduler == nil {
+		return ScheduleHint{}, nil
+	}
+	return repo.scheduler.Next(ctx, service.BuildID, items)
+}
@@ -0,0 +1,121 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/profefe/profefe/internal/pprof/profile"
+)
+
+type fakeIngestStorage struct {
+	profs []*Profile
+	pps   []*profile.Profile
+}
+
+func (s *fakeIngestStorage) CreateService(ctx context.Context, svc *Service) error { return nil }
+
+func (s *fakeIngestStorage) GetServices(ctx context.Context, filter *GetServicesFilter) ([]*Service, error) {
+	return nil, nil
+}
+
+func (s *fakeIngestStorage) CreateProfile(ctx context.Context, prof *Profile, pp *profile.Profile) error {
+	return nil
+}
+
+func (s *fakeIngestStorage) CreateProfiles(ctx context.Context, profs []*Profile, pps []*profile.Profile) error {
+	s.profs = append(s.profs, profs...)
+	s.pps = append(s.pps, pps...)
+	return nil
+}
+
+func (s *fakeIngestStorage) GetProfiles(ctx context.Context, filter *GetProfileFilter) ([]*profile.Profile, error) {
+	return nil, nil
+}
+
+func (s *fakeIngestStorage) GetProfile(ctx context.Context, filter *GetProfileFilter) (*profile.Profile, error) {
+	return nil, nil
+}
+
+type fakeScheduler struct {
+	hint         ScheduleHint
+	lastService  string
+	lastIngested []*IngestItem
+}
+
+func (s *fakeScheduler) Next(ctx context.Context, service string, ingested []*IngestItem) (ScheduleHint, error) {
+	s.lastService = service
+	s.lastIngested = ingested
+	return s.hint, nil
+}
+
+func emptyProfileBody(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	pp := &profile.Profile{SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}}}
+	if err := pp.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCreateProfilesBatchAndSchedule(t *testing.T) {
+	storage := &fakeIngestStorage{}
+	scheduler := &fakeScheduler{hint: ScheduleHint{Type: MutexProfile, Duration: 30 * time.Second}}
+	repo := NewRepository(nil, storage, WithScheduler(scheduler))
+
+	body := emptyProfileBody(t)
+	items := []*IngestItem{
+		{Type: CPUProfile, Labels: Labels{{Key: "region", Value: "us-east"}}, CreatedAt: time.Now(), Body: bytes.NewReader(body)},
+		{Type: HeapProfile, CreatedAt: time.Now(), Body: bytes.NewReader(body)},
+	}
+
+	hint, err := repo.CreateProfiles(context.Background(), &BatchIngestRequest{ID: "build-1", Token: "tok"}, items)
+	if err != nil {
+		t.Fatalf("CreateProfiles: %v", err)
+	}
+
+	if len(storage.pps) != 2 {
+		t.Fatalf("expected 2 profiles persisted, got %d", len(storage.pps))
+	}
+	if len(storage.profs[0].Labels) != 1 {
+		t.Errorf("expected the first profile's Labels to be carried through, got %v", storage.profs[0].Labels)
+	}
+	if scheduler.lastService != "build-1" {
+		t.Errorf("scheduler saw service %q, want build-1", scheduler.lastService)
+	}
+	if len(scheduler.lastIngested) != 2 {
+		t.Errorf("scheduler saw %d items, want 2", len(scheduler.lastIngested))
+	}
+	if hint != scheduler.hint {
+		t.Errorf("hint = %+v, want %+v", hint, scheduler.hint)
+	}
+}
+
+func TestCreateProfilesValidatesItems(t *testing.T) {
+	repo := NewRepository(nil, &fakeIngestStorage{})
+
+	_, err := repo.CreateProfiles(context.Background(), &BatchIngestRequest{ID: "build-1", Token: "tok"}, []*IngestItem{
+		{Type: UnknownProfile, CreatedAt: time.Now(), Body: bytes.NewReader(nil)},
+	})
+	if err == nil {
+		t.Fatal("expected an error for an item with an unknown profile type, got nil")
+	}
+}
+
+func TestCreateProfilesWithoutSchedulerReturnsZeroHint(t *testing.T) {
+	repo := NewRepository(nil, &fakeIngestStorage{})
+
+	body := emptyProfileBody(t)
+	hint, err := repo.CreateProfiles(context.Background(), &BatchIngestRequest{ID: "build-1", Token: "tok"}, []*IngestItem{
+		{Type: CPUProfile, CreatedAt: time.Now(), Body: bytes.NewReader(body)},
+	})
+	if err != nil {
+		t.Fatalf("CreateProfiles: %v", err)
+	}
+	if hint != (ScheduleHint{}) {
+		t.Errorf("hint = %+v, want the zero value", hint)
+	}
+}
@@ -0,0 +1,87 @@
+package profile
+
+import (
+	"context"
+	"io"
+
+	"github.com/profefe/profefe/internal/pprof/profile"
+	"golang.org/x/xerrors"
+)
+
+// MergeProfiles fetches all profiles matching req and merges them into a
+// single profile.Profile with profile.Merge. profile.Merge requires every
+// matching profile to have the same sample types; if they differ,
+// MergeProfiles returns an error instead of a merged profile.
+//
+// The merge is normalized for req.Type: CPU (and other cumulative) profiles
+// are summed across the window, since that's the quantity that actually
+// accumulated; in-use heap profiles are averaged instead, since summing
+// snapshots of a gauge would make the result grow with the number of
+// profiles collected rather than staying representative of the window.
+func (repo *Repository) MergeProfiles(ctx context.Context, req *GetProfilesRequest) (*profile.Profile, error) {
+	pps, err := repo.GetProfiles(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(pps) == 0 {
+		return nil, xerrors.Errorf("no profiles found: req %v", req)
+	}
+
+	merged, err := mergeProfiles(req.Type, pps)
+	if err != nil {
+		return nil, xerrors.Errorf("could not merge profiles: %w", err)
+	}
+
+	return merged, nil
+}
+
+// MergeProfilesTo is like MergeProfiles, but writes the merged profile to w
+// instead of returning it.
+func (repo *Repository) MergeProfilesTo(ctx context.Context, req *GetProfilesRequest, w io.Writer) error {
+	pp, err := repo.MergeProfiles(ctx, req)
+	if err != nil {
+		return err
+	}
+	return pp.Write(w)
+}
+
+func mergeProfiles(ptype ProfileType, pps []*profile.Profile) (*profile.Profile, error) {
+	merged, err := profile.Merge(pps)
+	if err != nil {
+		return nil, err
+	}
+
+	if ptype.Kind() == KindHeap {
+		averageSampleTypes(merged, heapGaugeSampleTypes, len(pps))
+	}
+
+	return merged, nil
+}
+
+// heapGaugeSampleTypes lists the heap sample types that represent a
+// point-in-time gauge (what's currently allocated) rather than a
+// monotonically increasing counter. Averaging only these across a merge
+// keeps the cumulative columns (alloc_space, alloc_objects) correct as
+// running totals instead of silently dividing them by the profile count.
+var heapGaugeSampleTypes = []string{"inuse_space", "inuse_objects"}
+
+// averageSampleTypes divides every sample value under the named sample
+// types by n, in place, leaving every other sample type untouched.
+func averageSampleTypes(pp *profile.Profile, names []string, n int) {
+	indices := make(map[int]bool, len(names))
+	for i, st := range pp.SampleType {
+		for _, name := range names {
+			if st.Type == name {
+				indices[i] = true
+			}
+		}
+	}
+
+	for _, sample := range pp.Sample {
+		for i := range indices {
+			if i < len(sample.Value) {
+				sample.Value[i] = int64(float64(sample.Value[i]) / float64(n))
+			}
+		}
+	}
+}
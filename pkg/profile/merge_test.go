@@ -0,0 +1,140 @@
+package profile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/profefe/profefe/internal/pprof/profile"
+)
+
+func TestMergeProfilesHeapAveragesGaugesOnly(t *testing.T) {
+	mkProfile := func(inuseSpace, allocSpace int64) *profile.Profile {
+		return &profile.Profile{
+			SampleType: []*profile.ValueType{
+				{Type: "inuse_space", Unit: "bytes"},
+				{Type: "alloc_space", Unit: "bytes"},
+			},
+			Sample: []*profile.Sample{
+				{Value: []int64{inuseSpace, allocSpace}},
+			},
+		}
+	}
+
+	pps := []*profile.Profile{mkProfile(100, 10), mkProfile(300, 20)}
+
+	merged, err := mergeProfiles(HeapProfile, pps)
+	if err != nil {
+		t.Fatalf("mergeProfiles: %v", err)
+	}
+
+	got := merged.Sample[0].Value
+	if got[0] != 200 {
+		t.Errorf("inuse_space = %d, want 200 (averaged)", got[0])
+	}
+	if got[1] != 30 {
+		t.Errorf("alloc_space = %d, want 30 (summed, not averaged)", got[1])
+	}
+}
+
+func TestMergeProfilesCPUIsNotAveraged(t *testing.T) {
+	mkProfile := func(samples int64) *profile.Profile {
+		return &profile.Profile{
+			SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+			Sample:     []*profile.Sample{{Value: []int64{samples}}},
+		}
+	}
+
+	pps := []*profile.Profile{mkProfile(5), mkProfile(7)}
+
+	merged, err := mergeProfiles(CPUProfile, pps)
+	if err != nil {
+		t.Fatalf("mergeProfiles: %v", err)
+	}
+
+	if got := merged.Sample[0].Value[0]; got != 12 {
+		t.Errorf("samples = %d, want 12 (summed)", got)
+	}
+}
+
+type fakeMergeStorage struct {
+	service  string
+	profiles []*profile.Profile
+}
+
+func (s *fakeMergeStorage) CreateService(ctx context.Context, svc *Service) error { return nil }
+
+func (s *fakeMergeStorage) GetServices(ctx context.Context, filter *GetServicesFilter) ([]*Service, error) {
+	return nil, nil
+}
+
+func (s *fakeMergeStorage) CreateProfile(ctx context.Context, prof *Profile, pp *profile.Profile) error {
+	s.profiles = append(s.profiles, pp)
+	return nil
+}
+
+func (s *fakeMergeStorage) CreateProfiles(ctx context.Context, profs []*Profile, pps []*profile.Profile) error {
+	s.profiles = append(s.profiles, pps...)
+	return nil
+}
+
+func (s *fakeMergeStorage) GetProfiles(ctx context.Context, filter *GetProfileFilter) ([]*profile.Profile, error) {
+	if filter.Service != s.service {
+		return nil, nil
+	}
+	return s.profiles, nil
+}
+
+func (s *fakeMergeStorage) GetProfile(ctx context.Context, filter *GetProfileFilter) (*profile.Profile, error) {
+	pps, err := s.GetProfiles(ctx, filter)
+	if err != nil || len(pps) == 0 {
+		return nil, err
+	}
+	return pps[0], nil
+}
+
+func TestMergeProfilesEmptySet(t *testing.T) {
+	repo := NewRepository(nil, &fakeMergeStorage{service: "known-service"})
+
+	_, err := repo.MergeProfiles(context.Background(), &GetProfilesRequest{
+		Service: "unknown-service",
+		Type:    CPUProfile,
+		From:    time.Now().Add(-time.Hour),
+		To:      time.Now(),
+	})
+	if err == nil {
+		t.Fatal("expected an error for an empty profile set, got nil")
+	}
+}
+
+func TestMergeProfilesMultiProfile(t *testing.T) {
+	storage := &fakeMergeStorage{
+		service: "known-service",
+		profiles: []*profile.Profile{
+			{
+				SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+				Sample:     []*profile.Sample{{Value: []int64{5}}},
+			},
+			{
+				SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+				Sample:     []*profile.Sample{{Value: []int64{7}}},
+			},
+		},
+	}
+	repo := NewRepository(nil, storage)
+
+	merged, err := repo.MergeProfiles(context.Background(), &GetProfilesRequest{
+		Service: "known-service",
+		Type:    CPUProfile,
+		Labels:  Labels{{Key: "region", Value: "us-east"}},
+		From:    time.Now().Add(-time.Hour),
+		To:      time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("MergeProfiles: %v", err)
+	}
+
+	if got := merged.Sample[0].Value[0]; got != 12 {
+		t.Errorf("samples = %d, want 12", got)
+	}
+}
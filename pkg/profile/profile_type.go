@@ -1,60 +1,114 @@
 package profile
 
 import (
-	"fmt"
+	"encoding/json"
 	"strings"
 )
 
-type ProfileType int8
+// Kind is the well-known family a ProfileType belongs to. It's the thing
+// storage backends and the merge pipeline branch on; ProfileType itself can
+// carry an arbitrary user tag on top of it. The delta pipeline deliberately
+// doesn't branch on Kind: DeltaStrategy lets callers name pprof sample
+// types directly (e.g. "inuse_space"), since which columns to diff versus
+// sum is a property of the sample type, not of the profile's Kind.
+type Kind string
 
 const (
-	UnknownProfile ProfileType = iota
-	CPUProfile
-	HeapProfile
-	BlockProfile
-	MutexProfile
-	GoroutineProfile
-
-	OtherProfile = 127
+	KindUnknown   Kind = ""
+	KindCPU       Kind = "cpu"
+	KindHeap      Kind = "heap"
+	KindBlock     Kind = "block"
+	KindMutex     Kind = "mutex"
+	KindGoroutine Kind = "goroutine"
+	KindOther     Kind = "other"
 )
 
+// ProfileType identifies the kind of a profile. It used to be a closed int8
+// enum with a single catch-all OtherProfile value; it's now a Kind plus an
+// optional tag, so deployments can push non-standard profiles (JFR
+// conversions, tracing, allocation-object profiles, custom application
+// counters) and get them back under their own distinct type instead of
+// having them collapse into "other". The well-known kinds below are kept as
+// ProfileType values for back-compat with existing call sites.
+type ProfileType struct {
+	kind Kind
+	tag  string
+}
+
+var (
+	UnknownProfile   = ProfileType{kind: KindUnknown}
+	CPUProfile       = ProfileType{kind: KindCPU}
+	HeapProfile      = ProfileType{kind: KindHeap}
+	BlockProfile     = ProfileType{kind: KindBlock}
+	MutexProfile     = ProfileType{kind: KindMutex}
+	GoroutineProfile = ProfileType{kind: KindGoroutine}
+	OtherProfile     = ProfileType{kind: KindOther}
+)
+
+// NewCustomProfileType returns the ProfileType for a user-defined tag that
+// isn't one of the well-known kinds above, e.g. NewCustomProfileType("jfr-alloc").
+func NewCustomProfileType(tag string) ProfileType {
+	return ProfileType{kind: KindOther, tag: tag}
+}
+
+// Kind reports the well-known kind backing ptype. Custom types report
+// KindOther; use IsCustom to tell a custom type apart from the built-in
+// OtherProfile.
+func (ptype ProfileType) Kind() Kind {
+	return ptype.kind
+}
+
+// IsCustom reports whether ptype carries a user tag rather than being
+// exactly one of the built-in kinds.
+func (ptype ProfileType) IsCustom() bool {
+	return ptype.tag != ""
+}
+
 func (ptype *ProfileType) FromString(s string) error {
 	s = strings.TrimSpace(s)
-	switch s {
-	case "cpu":
+	switch Kind(s) {
+	case KindUnknown, Kind("unknown"):
+		*ptype = UnknownProfile
+	case KindCPU:
 		*ptype = CPUProfile
-	case "heap":
+	case KindHeap:
 		*ptype = HeapProfile
-	case "block":
+	case KindBlock:
 		*ptype = BlockProfile
-	case "mutex":
+	case KindMutex:
 		*ptype = MutexProfile
-	case "goroutine":
+	case KindGoroutine:
 		*ptype = GoroutineProfile
-	case "other":
+	case KindOther:
 		*ptype = OtherProfile
 	default:
-		*ptype = UnknownProfile
+		*ptype = NewCustomProfileType(s)
 	}
 	return nil
 }
 
 func (ptype ProfileType) String() string {
-	switch ptype {
-	case UnknownProfile:
+	if ptype.tag != "" {
+		return ptype.tag
+	}
+	if ptype.kind == KindUnknown {
 		return "unknown"
-	case CPUProfile:
-		return "cpu"
-	case HeapProfile:
-		return "heap"
-	case BlockProfile:
-		return "block"
-	case MutexProfile:
-		return "mutex"
-	case GoroutineProfile:
-		return "goroutine"
-	case OtherProfile:
-		return "other"
 	}
-	return fmt.Sprintf("%d", ptype)
+	return string(ptype.kind)
+}
+
+// MarshalJSON encodes ptype the same way String does, so custom tags
+// round-trip through the HTTP API and storage layers instead of collapsing
+// to "other".
+func (ptype ProfileType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ptype.String())
+}
+
+// UnmarshalJSON is the inverse of MarshalJSON, via FromString.
+func (ptype *ProfileType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return ptype.FromString(s)
 }
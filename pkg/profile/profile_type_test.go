@@ -0,0 +1,31 @@
+package profile
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestProfileTypeJSONRoundTrip(t *testing.T) {
+	cases := []ProfileType{
+		CPUProfile,
+		HeapProfile,
+		OtherProfile,
+		NewCustomProfileType("jfr-alloc"),
+	}
+
+	for _, want := range cases {
+		data, err := json.Marshal(want)
+		if err != nil {
+			t.Fatalf("Marshal(%v): %v", want, err)
+		}
+
+		var got ProfileType
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+
+		if got != want {
+			t.Errorf("round trip of %v: got %v", want, got)
+		}
+	}
+}
@@ -13,15 +13,35 @@ import (
 )
 
 type Repository struct {
-	logger  *logger.Logger
-	storage Storage
+	logger     *logger.Logger
+	storage    Storage
+	scheduler  ProfileScheduler
+	symbolizer Symbolizer
+	debugInfo  DebugInfoStore
 }
 
-func NewRepository(log *logger.Logger, st Storage) *Repository {
-	return &Repository{
-		logger:  log,
-		storage: st,
+// Option configures optional Repository behavior not every deployment
+// needs, e.g. a ProfileScheduler to steer continuous profiling agents.
+type Option func(*Repository)
+
+// WithScheduler sets the ProfileScheduler CreateProfiles consults for the
+// next collection hint returned to an agent after a batch ingest.
+func WithScheduler(s ProfileScheduler) Option {
+	return func(repo *Repository) {
+		repo.scheduler = s
+	}
+}
+
+func NewRepository(log *logger.Logger, st Storage, opts ...Option) *Repository {
+	repo := &Repository{
+		logger:     log,
+		storage:    st,
+		symbolizer: noopSymbolizer{},
+	}
+	for _, opt := range opts {
+		opt(repo)
 	}
+	return repo
 }
 
 type CreateServiceRequest struct {
@@ -130,6 +150,8 @@ func (repo *Repository) CreateProfile(ctx context.Context, req *CreateProfileReq
 		return xerrors.Errorf("could not parse profile: %w", err)
 	}
 
+	symbolizeProfile(ctx, repo.symbolizer, pp)
+
 	return repo.storage.CreateProfile(ctx, prof, pp)
 }
 
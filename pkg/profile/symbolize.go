@@ -0,0 +1,151 @@
+package profile
+
+import (
+	"context"
+	"io"
+
+	"github.com/profefe/profefe/internal/pprof/profile"
+	"golang.org/x/xerrors"
+)
+
+// Symbolizer resolves addresses in a profile's locations back to
+// function/line info, keyed by the mapping's BuildID. Agents scraping
+// /debug/pprof/profile from stripped binaries, or from Go programs built
+// with -trimpath, upload profiles whose locations carry only raw addresses;
+// a Symbolizer lets the server backfill Function/Line from debug info
+// uploaded ahead of time, via DebugInfoStore.
+type Symbolizer interface {
+	// Symbolize resolves addr within the mapping identified by buildID,
+	// returning the inlined function/line stack for that address (innermost
+	// frame first). It returns an error if no debug info is available for
+	// buildID.
+	Symbolize(ctx context.Context, buildID string, addr uint64) ([]profile.Line, error)
+}
+
+// noopSymbolizer is the default Symbolizer: it leaves every location
+// untouched. It's used when no debug-info store is configured.
+type noopSymbolizer struct{}
+
+func (noopSymbolizer) Symbolize(ctx context.Context, buildID string, addr uint64) ([]profile.Line, error) {
+	return nil, nil
+}
+
+// DebugInfo is a single debug-info blob (e.g. an unstripped copy of the
+// binary, or a standalone DWARF file) uploaded ahead of time for a given
+// BuildID.
+type DebugInfo struct {
+	BuildID string
+	Data    io.Reader
+}
+
+func (info *DebugInfo) Validate() error {
+	if info == nil {
+		return xerrors.New("nil debug info")
+	}
+	if info.BuildID == "" {
+		return xerrors.Errorf("buildID empty: info %v", info)
+	}
+	return nil
+}
+
+// DebugInfoStore persists debug-info blobs so a Symbolizer can look them up
+// by BuildID. Implementations wrap a blob store (S3, GCS, local
+// filesystem). This package contains no implementation, and no Symbolizer
+// in it reads from a DebugInfoStore: CreateDebugInfo writes to the store
+// set by WithDebugInfoStore, and the Symbolizer is set on its own by
+// WithSymbolizer.
+type DebugInfoStore interface {
+	Put(ctx context.Context, info *DebugInfo) error
+	Get(ctx context.Context, buildID string) (io.ReadCloser, error)
+}
+
+// WithSymbolizer sets the Symbolizer CreateProfile runs over incoming
+// profiles before persisting them.
+func WithSymbolizer(sym Symbolizer) Option {
+	return func(repo *Repository) {
+		repo.symbolizer = sym
+	}
+}
+
+// WithDebugInfoStore sets the store CreateDebugInfo persists uploaded debug
+// info into.
+func WithDebugInfoStore(store DebugInfoStore) Option {
+	return func(repo *Repository) {
+		repo.debugInfo = store
+	}
+}
+
+// CreateDebugInfo stores r as the debug info for buildID, for later use by
+// repo's Symbolizer.
+func (repo *Repository) CreateDebugInfo(ctx context.Context, buildID string, r io.Reader) error {
+	info := &DebugInfo{BuildID: buildID, Data: r}
+	if err := info.Validate(); err != nil {
+		return err
+	}
+	if repo.debugInfo == nil {
+		return xerrors.New("no debug info store configured")
+	}
+	return repo.debugInfo.Put(ctx, info)
+}
+
+// symbolizeProfile backfills Function/Line on locations whose mapping has a
+// BuildID but whose Line list is empty, using sym to resolve each address.
+// Locations sym can't resolve (e.g. no debug info uploaded yet for that
+// BuildID) are left as-is rather than failing the whole profile.
+//
+// The pprof encoder only serializes functions reachable from pp.Function,
+// not ones merely referenced by a Location's Line; every *profile.Function
+// a Symbolizer hands back is therefore deduped against pp.Function and
+// given a fresh, profile-scoped ID before being attached, so the names
+// survive pp.Write instead of disappearing on the next round trip.
+func symbolizeProfile(ctx context.Context, sym Symbolizer, pp *profile.Profile) {
+	var nextID uint64
+	functionsByKey := make(map[functionKey]*profile.Function, len(pp.Function))
+	for _, fn := range pp.Function {
+		functionsByKey[functionKeyOf(fn)] = fn
+		if fn.ID > nextID {
+			nextID = fn.ID
+		}
+	}
+
+	for _, loc := range pp.Location {
+		if len(loc.Line) > 0 || loc.Mapping == nil || loc.Mapping.BuildID == "" {
+			continue
+		}
+
+		lines, err := sym.Symbolize(ctx, loc.Mapping.BuildID, loc.Address)
+		if err != nil || len(lines) == 0 {
+			continue
+		}
+
+		for i, line := range lines {
+			if line.Function == nil {
+				continue
+			}
+
+			key := functionKeyOf(line.Function)
+			fn, ok := functionsByKey[key]
+			if !ok {
+				nextID++
+				fn = line.Function
+				fn.ID = nextID
+				functionsByKey[key] = fn
+				pp.Function = append(pp.Function, fn)
+			}
+			lines[i].Function = fn
+		}
+
+		loc.Line = lines
+	}
+}
+
+// functionKey dedupes functions returned by a Symbolizer against both each
+// other and pp.Function, so the same symbol resolved from two locations
+// doesn't get persisted twice under two different IDs.
+type functionKey struct {
+	name, systemName, filename string
+}
+
+func functionKeyOf(fn *profile.Function) functionKey {
+	return functionKey{fn.Name, fn.SystemName, fn.Filename}
+}
@@ -0,0 +1,87 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/profefe/profefe/internal/pprof/profile"
+)
+
+type fakeSymbolizer struct {
+	lines []profile.Line
+}
+
+func (s fakeSymbolizer) Symbolize(ctx context.Context, buildID string, addr uint64) ([]profile.Line, error) {
+	return s.lines, nil
+}
+
+func TestSymbolizeProfileSurvivesWriteParseRoundTrip(t *testing.T) {
+	mapping := &profile.Mapping{ID: 1, BuildID: "deadbeef"}
+	loc := &profile.Location{ID: 1, Mapping: mapping, Address: 0x1000}
+	pp := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+		Mapping:    []*profile.Mapping{mapping},
+		Location:   []*profile.Location{loc},
+		Sample: []*profile.Sample{
+			{Location: []*profile.Location{loc}, Value: []int64{1}},
+		},
+	}
+
+	sym := fakeSymbolizer{lines: []profile.Line{
+		{Function: &profile.Function{Name: "main.hot", SystemName: "main.hot", Filename: "main.go"}, Line: 42},
+	}}
+
+	symbolizeProfile(context.Background(), sym, pp)
+
+	if len(pp.Function) != 1 {
+		t.Fatalf("expected the resolved function to be appended to pp.Function, got %d entries", len(pp.Function))
+	}
+	if pp.Function[0].ID == 0 {
+		t.Fatal("expected a non-zero, profile-scoped function ID")
+	}
+
+	var buf bytes.Buffer
+	if err := pp.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	roundTripped, err := profile.Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if len(roundTripped.Function) != 1 || roundTripped.Function[0].Name != "main.hot" {
+		t.Fatalf("function did not survive the round trip: %+v", roundTripped.Function)
+	}
+	if len(roundTripped.Location) != 1 || len(roundTripped.Location[0].Line) != 1 {
+		t.Fatalf("location line did not survive the round trip: %+v", roundTripped.Location)
+	}
+	if got := roundTripped.Location[0].Line[0].Function.Name; got != "main.hot" {
+		t.Errorf("location's function name = %q, want main.hot", got)
+	}
+}
+
+func TestSymbolizeProfileDedupesSharedFunction(t *testing.T) {
+	mapping := &profile.Mapping{ID: 1, BuildID: "deadbeef"}
+	locA := &profile.Location{ID: 1, Mapping: mapping, Address: 0x1000}
+	locB := &profile.Location{ID: 2, Mapping: mapping, Address: 0x2000}
+	pp := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+		Mapping:    []*profile.Mapping{mapping},
+		Location:   []*profile.Location{locA, locB},
+	}
+
+	sym := fakeSymbolizer{lines: []profile.Line{
+		{Function: &profile.Function{Name: "main.hot", SystemName: "main.hot", Filename: "main.go"}, Line: 42},
+	}}
+
+	symbolizeProfile(context.Background(), sym, pp)
+
+	if len(pp.Function) != 1 {
+		t.Fatalf("expected the shared function to be deduped into a single pp.Function entry, got %d", len(pp.Function))
+	}
+	if locA.Line[0].Function != locB.Line[0].Function {
+		t.Error("expected both locations to reference the same *profile.Function")
+	}
+}